@@ -18,9 +18,18 @@ import (
 	"strings"
 )
 
-// CreateReplacedNetPkgOverlayFile create an Overlay file to replace net.Listen and net.Dialer.DialContext with functions from wasi-go-net.
+// CreateReplacedNetPkgOverlayFile create an Overlay file to replace the net package functions and
+// methods described by DefaultRules with functions from wasi-go-net.
 func CreateReplacedNetPkgOverlayFile(ctx context.Context) (*OverlayFile, error) {
-	srcs, err := GetReplacedNetSources(ctx)
+	return CreateReplacedNetPkgOverlayFileWithRules(ctx, DefaultRules()...)
+}
+
+// CreateReplacedNetPkgOverlayFileWithRules is CreateReplacedNetPkgOverlayFile parameterized over
+// rules instead of always applying DefaultRules. Downstream users that need to additionally
+// override other net package entry points (Interfaces, InterfaceAddrs, LookupPort, ...) can pass
+// DefaultRules() plus their own RewriteRules here without forking this package.
+func CreateReplacedNetPkgOverlayFileWithRules(ctx context.Context, rules ...RewriteRule) (*OverlayFile, error) {
+	srcs, err := GetReplacedNetSourcesWithRules(ctx, rules...)
 	if err != nil {
 		return nil, err
 	}
@@ -32,48 +41,31 @@ type ReplacedNetSource struct {
 	Content []byte
 }
 
-// GetReplacedNetSources return the source code after replacing net.Listen and net.Dialer.DialContext with functions from wasi-go-net.
+// GetReplacedNetSources return the source code after replacing the net package functions and
+// methods described by DefaultRules with functions from wasi-go-net.
 func GetReplacedNetSources(ctx context.Context) ([]*ReplacedNetSource, error) {
+	return GetReplacedNetSourcesWithRules(ctx, DefaultRules()...)
+}
+
+// GetReplacedNetSourcesWithRules is GetReplacedNetSources parameterized over rules.
+func GetReplacedNetSourcesWithRules(ctx context.Context, rules ...RewriteRule) ([]*ReplacedNetSource, error) {
 	netPkgFiles, err := netPkgGoFiles(ctx)
 	if err != nil {
 		return nil, err
 	}
-	paths := findSourcePaths(
-		netPkgFiles,
-		func(decl *ast.FuncDecl) bool {
-			if decl.Name.Name != "DialContext" {
-				return false
-			}
-			if decl.Recv == nil {
-				return false
-			}
-			if len(decl.Recv.List) == 0 {
-				return false
-			}
-			if len(decl.Recv.List[0].Names) == 0 {
-				return false
-			}
-			star, ok := decl.Recv.List[0].Type.(*ast.StarExpr)
-			if !ok {
-				return false
-			}
-			ident, ok := star.X.(*ast.Ident)
-			if !ok {
-				return false
-			}
-			return ident.Name == "Dialer"
-		},
-		func(decl *ast.FuncDecl) bool {
-			return decl.Name.Name == "Listen" && decl.Recv == nil
-		},
-	)
+	matchers := make([]func(*ast.FuncDecl) bool, 0, len(rules))
+	for _, rule := range rules {
+		rule := rule
+		matchers = append(matchers, rule.Match)
+	}
+	paths := findSourcePaths(netPkgFiles, matchers...)
 	if len(paths) == 0 {
 		return nil, errors.New("failed to find net package source files")
 	}
 
 	ret := make([]*ReplacedNetSource, 0, len(paths))
 	for _, path := range paths {
-		content, err := createReplacedNetSource(path)
+		content, err := createReplacedNetSourceWithRules(path, rules)
 		if err != nil {
 			return nil, err
 		}
@@ -88,6 +80,9 @@ func GetReplacedNetSources(ctx context.Context) ([]*ReplacedNetSource, error) {
 type OverlayFile struct {
 	path         string
 	tmpFilePaths []string
+	// cached marks an OverlayFile returned from the on-disk cache in overlay_cache.go: the cache
+	// owns the lifetime of path (and the rewritten files it references), so Close is a no-op.
+	cached bool
 }
 
 func (f *OverlayFile) Path() string {
@@ -95,6 +90,9 @@ func (f *OverlayFile) Path() string {
 }
 
 func (f *OverlayFile) Close() {
+	if f.cached {
+		return
+	}
 	_ = os.Remove(f.path)
 	for _, path := range f.tmpFilePaths {
 		_ = os.Remove(path)
@@ -206,8 +204,412 @@ func findSourcePaths(netPkgFiles []string, matchers ...func(*ast.FuncDecl) bool)
 	return paths
 }
 
-// createModifiedNetFile creates a override version of a net package file using AST manipulation.
+// Param describes a single parameter (or result) of a ShimDecl. Type is parsed as a Go type
+// expression, so arbitrarily qualified types (context.Context, *os.File, ...) can be described
+// without touching the AST construction code below.
+type Param struct {
+	Name string
+	Type string
+}
+
+// ShimDecl describes the body-less, //go:linkname-annotated function a RewriteRule generates to
+// forward a rewritten net package function or method to wasi-go-net.
+type ShimDecl struct {
+	// Name is the name of the generated shim function.
+	Name string
+	// LinknameTarget is the wasi-go-net symbol the shim links to.
+	LinknameTarget string
+	Params         []Param
+	Results        []Param
+}
+
+// FuncDecl builds the body-less, //go:linkname-annotated function declaration described by s.
+func (s ShimDecl) FuncDecl() (*ast.FuncDecl, error) {
+	params := &ast.FieldList{}
+	for _, p := range s.Params {
+		typeExpr, err := parseTypeExpr(p.Type)
+		if err != nil {
+			return nil, err
+		}
+		params.List = append(params.List, &ast.Field{
+			Names: []*ast.Ident{{Name: p.Name}},
+			Type:  typeExpr,
+		})
+	}
+
+	results := &ast.FieldList{}
+	for _, r := range s.Results {
+		typeExpr, err := parseTypeExpr(r.Type)
+		if err != nil {
+			return nil, err
+		}
+		results.List = append(results.List, &ast.Field{Type: typeExpr})
+	}
+
+	return &ast.FuncDecl{
+		Doc: &ast.CommentGroup{
+			List: []*ast.Comment{
+				{Text: fmt.Sprintf("//go:linkname %s %s", s.Name, s.LinknameTarget)},
+			},
+		},
+		Name: &ast.Ident{Name: s.Name},
+		Type: &ast.FuncType{
+			Params:  params,
+			Results: results,
+		},
+		Body: nil, // No body for external linkage
+	}, nil
+}
+
+// RewriteRule describes how to redirect a single net package function or method to wasi-go-net.
+// Match reports whether a given declaration should be rewritten; NewBody builds its replacement
+// body (typically a single call into Shim's generated function); Shim describes the body-less
+// //go:linkname function that replacement body calls into. Composing rules this way lets
+// downstream users add their own entry points without touching the AST construction code in this
+// file - see DefaultRules for the rules this package rewrites on its own.
+type RewriteRule struct {
+	Match   func(*ast.FuncDecl) bool
+	NewBody func(*ast.FuncDecl) *ast.BlockStmt
+	Shim    ShimDecl
+}
+
+// netRewriteRule is the compact, table-driven description DefaultRules is built from. It covers
+// the common case of matching a function or method by name and forwarding all of its parameters
+// (plus, optionally, its receiver) into a //go:linkname shim.
+type netRewriteRule struct {
+	// funcName is the name of the function or method to match.
+	funcName string
+	// receiverType is the pointer receiver's type name, e.g. "Dialer". Left empty to match
+	// package-level functions.
+	receiverType string
+	// passReceiver forwards the method receiver itself as the shim's first argument, for
+	// rules that need to inspect fields on the receiver (e.g. ListenConfig).
+	passReceiver bool
+	// shimName is the name of the generated, body-less //go:linkname function.
+	shimName string
+	// linknameTarget is the wasi-go-net symbol the shim links to.
+	linknameTarget string
+	params         []Param
+	results        []Param
+}
+
+// netRewriteRules is the table DefaultRules converts into the built-in []RewriteRule.
+var netRewriteRules = []netRewriteRule{
+	{
+		funcName:       "DialContext",
+		receiverType:   "Dialer",
+		shimName:       "_dialContext",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.DialContext",
+		params: []Param{
+			{"ctx", "context.Context"},
+			{"network", "string"},
+			{"address", "string"},
+		},
+		results: []Param{{Type: "Conn"}, {Type: "error"}},
+	},
+	{
+		funcName:       "Dial",
+		shimName:       "_dial",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.Dial",
+		params: []Param{
+			{"network", "string"},
+			{"address", "string"},
+		},
+		results: []Param{{Type: "Conn"}, {Type: "error"}},
+	},
+	{
+		funcName:       "Dial",
+		receiverType:   "Dialer",
+		shimName:       "_dialerDial",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.Dial",
+		params: []Param{
+			{"network", "string"},
+			{"address", "string"},
+		},
+		results: []Param{{Type: "Conn"}, {Type: "error"}},
+	},
+	{
+		funcName:       "Listen",
+		shimName:       "_listen",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.Listen",
+		params: []Param{
+			{"network", "string"},
+			{"address", "string"},
+		},
+		results: []Param{{Type: "Listener"}, {Type: "error"}},
+	},
+	{
+		funcName:       "ListenPacket",
+		shimName:       "_listenPacket",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.ListenPacket",
+		params: []Param{
+			{"network", "string"},
+			{"address", "string"},
+		},
+		results: []Param{{Type: "PacketConn"}, {Type: "error"}},
+	},
+	{
+		funcName:       "Listen",
+		receiverType:   "ListenConfig",
+		passReceiver:   true,
+		shimName:       "_listenConfigListen",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.ListenConfigListen",
+		params: []Param{
+			{"ctx", "context.Context"},
+			{"network", "string"},
+			{"address", "string"},
+		},
+		results: []Param{{Type: "Listener"}, {Type: "error"}},
+	},
+	{
+		funcName:       "ListenPacket",
+		receiverType:   "ListenConfig",
+		passReceiver:   true,
+		shimName:       "_listenConfigListenPacket",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.ListenConfigListenPacket",
+		params: []Param{
+			{"ctx", "context.Context"},
+			{"network", "string"},
+			{"address", "string"},
+		},
+		results: []Param{{Type: "PacketConn"}, {Type: "error"}},
+	},
+	{
+		funcName:       "FileConn",
+		shimName:       "_fileConn",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.FileConn",
+		params:         []Param{{"f", "*os.File"}},
+		results:        []Param{{Type: "Conn"}, {Type: "error"}},
+	},
+	{
+		funcName:       "FileListener",
+		shimName:       "_fileListener",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.FileListener",
+		params:         []Param{{"f", "*os.File"}},
+		results:        []Param{{Type: "Listener"}, {Type: "error"}},
+	},
+	{
+		funcName:       "FilePacketConn",
+		shimName:       "_filePacketConn",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.FilePacketConn",
+		params:         []Param{{"f", "*os.File"}},
+		results:        []Param{{Type: "PacketConn"}, {Type: "error"}},
+	},
+	{
+		funcName:       "LookupHost",
+		receiverType:   "Resolver",
+		passReceiver:   true,
+		shimName:       "_resolverLookupHost",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.ResolverLookupHost",
+		params: []Param{
+			{"ctx", "context.Context"},
+			{"host", "string"},
+		},
+		results: []Param{{Type: "[]string"}, {Type: "error"}},
+	},
+	{
+		funcName:       "LookupIPAddr",
+		receiverType:   "Resolver",
+		passReceiver:   true,
+		shimName:       "_resolverLookupIPAddr",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.ResolverLookupIPAddr",
+		params: []Param{
+			{"ctx", "context.Context"},
+			{"host", "string"},
+		},
+		results: []Param{{Type: "[]IPAddr"}, {Type: "error"}},
+	},
+	{
+		funcName:       "LookupCNAME",
+		receiverType:   "Resolver",
+		passReceiver:   true,
+		shimName:       "_resolverLookupCNAME",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.ResolverLookupCNAME",
+		params: []Param{
+			{"ctx", "context.Context"},
+			{"host", "string"},
+		},
+		results: []Param{{Type: "string"}, {Type: "error"}},
+	},
+	{
+		funcName:       "LookupSRV",
+		receiverType:   "Resolver",
+		passReceiver:   true,
+		shimName:       "_resolverLookupSRV",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.ResolverLookupSRV",
+		params: []Param{
+			{"ctx", "context.Context"},
+			{"service", "string"},
+			{"proto", "string"},
+			{"name", "string"},
+		},
+		results: []Param{{Type: "string"}, {Type: "[]*SRV"}, {Type: "error"}},
+	},
+	{
+		funcName:       "LookupMX",
+		receiverType:   "Resolver",
+		passReceiver:   true,
+		shimName:       "_resolverLookupMX",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.ResolverLookupMX",
+		params: []Param{
+			{"ctx", "context.Context"},
+			{"name", "string"},
+		},
+		results: []Param{{Type: "[]*MX"}, {Type: "error"}},
+	},
+	{
+		funcName:       "LookupNS",
+		receiverType:   "Resolver",
+		passReceiver:   true,
+		shimName:       "_resolverLookupNS",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.ResolverLookupNS",
+		params: []Param{
+			{"ctx", "context.Context"},
+			{"name", "string"},
+		},
+		results: []Param{{Type: "[]*NS"}, {Type: "error"}},
+	},
+	{
+		funcName:       "LookupTXT",
+		receiverType:   "Resolver",
+		passReceiver:   true,
+		shimName:       "_resolverLookupTXT",
+		linknameTarget: "github.com/goccy/wasi-go-net/wasip1.ResolverLookupTXT",
+		params: []Param{
+			{"ctx", "context.Context"},
+			{"name", "string"},
+		},
+		results: []Param{{Type: "[]string"}, {Type: "error"}},
+	},
+}
+
+// DefaultRules returns the built-in RewriteRules this package has always applied: the
+// FileConn/FileListener/FilePacketConn, Dial/DialContext/Listen/ListenPacket/ListenConfig, and
+// Resolver rewrites, each converted from netRewriteRules into the public RewriteRule form.
+func DefaultRules() []RewriteRule {
+	rules := make([]RewriteRule, len(netRewriteRules))
+	for i, r := range netRewriteRules {
+		rules[i] = r.rule()
+	}
+	return rules
+}
+
+// receiverName reports the declared receiver's type name and variable name, e.g.
+// ("Dialer", "d") for `func (d *Dialer) Dial(...)`.
+func receiverName(recv *ast.FieldList) (typeName, varName string, ok bool) {
+	if recv == nil || len(recv.List) == 0 || len(recv.List[0].Names) == 0 {
+		return "", "", false
+	}
+	star, ok := recv.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return "", "", false
+	}
+	ident, ok := star.X.(*ast.Ident)
+	if !ok {
+		return "", "", false
+	}
+	return ident.Name, recv.List[0].Names[0].Name, true
+}
+
+// matches reports whether decl is the function or method described by r.
+func (r netRewriteRule) matches(decl *ast.FuncDecl) bool {
+	if decl.Name.Name != r.funcName {
+		return false
+	}
+	recvType, _, hasRecv := receiverName(decl.Recv)
+	if r.receiverType == "" {
+		return !hasRecv
+	}
+	return hasRecv && recvType == r.receiverType
+}
+
+// newBody builds decl's replacement body: a single call into the rule's shim function.
+func (r netRewriteRule) newBody(decl *ast.FuncDecl) *ast.BlockStmt {
+	args := make([]ast.Expr, 0, len(r.params)+1)
+	if r.passReceiver {
+		_, varName, _ := receiverName(decl.Recv)
+		args = append(args, &ast.Ident{Name: varName})
+	}
+	for _, p := range r.params {
+		args = append(args, &ast.Ident{Name: p.Name})
+	}
+	return &ast.BlockStmt{
+		List: []ast.Stmt{
+			&ast.ReturnStmt{
+				Results: []ast.Expr{
+					&ast.CallExpr{
+						Fun:  &ast.Ident{Name: r.shimName},
+						Args: args,
+					},
+				},
+			},
+		},
+	}
+}
+
+// shim builds the ShimDecl backing r.shimName, prepending a "recv" parameter when the rule
+// forwards its receiver.
+func (r netRewriteRule) shim() ShimDecl {
+	params := make([]Param, 0, len(r.params)+1)
+	if r.passReceiver {
+		params = append(params, Param{Name: "recv", Type: "*" + r.receiverType})
+	}
+	params = append(params, r.params...)
+	return ShimDecl{
+		Name:           r.shimName,
+		LinknameTarget: r.linknameTarget,
+		Params:         params,
+		Results:        r.results,
+	}
+}
+
+// rule converts r into the public RewriteRule form returned by DefaultRules.
+func (r netRewriteRule) rule() RewriteRule {
+	return RewriteRule{
+		Match:   r.matches,
+		NewBody: r.newBody,
+		Shim:    r.shim(),
+	}
+}
+
+// parseTypeExpr parses a Go type expression such as "context.Context" or "*os.File" into an
+// ast.Expr. The returned expression has its positions cleared so it can be embedded in an AST
+// that belongs to a different token.FileSet without confusing the printer's line-break logic.
+func parseTypeExpr(src string) (ast.Expr, error) {
+	expr, err := parser.ParseExpr(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse type %q: %w", src, err)
+	}
+	return clearExprPos(expr), nil
+}
+
+// clearExprPos recursively resets the token.Pos fields of expr to token.NoPos.
+func clearExprPos(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		e.NamePos = token.NoPos
+	case *ast.SelectorExpr:
+		clearExprPos(e.X)
+		e.Sel.NamePos = token.NoPos
+	case *ast.StarExpr:
+		e.Star = token.NoPos
+		clearExprPos(e.X)
+	case *ast.ArrayType:
+		e.Lbrack = token.NoPos
+		clearExprPos(e.Elt)
+	}
+	return expr
+}
+
+// createReplacedNetSource creates an override version of a net package file using the built-in
+// DefaultRules.
 func createReplacedNetSource(path string) ([]byte, error) {
+	return createReplacedNetSourceWithRules(path, DefaultRules())
+}
+
+// createReplacedNetSourceWithRules creates an override version of a net package file using AST
+// manipulation, applying each of rules to the function and method declarations it finds.
+func createReplacedNetSourceWithRules(path string, rules []RewriteRule) ([]byte, error) {
 	src, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
@@ -255,155 +657,49 @@ func createReplacedNetSource(path string) ([]byte, error) {
 		}
 	}
 
-	var (
-		foundDialContext bool
-		foundListen      bool
-	)
-
-	// Find the target functions and modify them
+	// Find the target functions and modify them, recording which rules matched in table
+	// order so the shim declarations below come out in a deterministic sequence.
+	matched := make([]bool, len(rules))
 	for _, decl := range astFile.Decls {
-		if funcDecl, ok := decl.(*ast.FuncDecl); ok {
-			funcName := funcDecl.Name.Name
-			switch {
-			case funcName == "DialContext" && funcDecl.Recv != nil:
-				// replace function body of DialContext method.
-				//
-				// func (d *Dialer) DialContext(ctx context.Context, network, address string) (Conn, error) {
-				//   return _dialContext(ctx, network, address)
-				// }
-				funcDecl.Body = &ast.BlockStmt{
-					List: []ast.Stmt{
-						&ast.ReturnStmt{
-							Results: []ast.Expr{
-								&ast.CallExpr{
-									Fun: &ast.Ident{Name: "_dialContext"},
-									Args: []ast.Expr{
-										&ast.Ident{Name: "ctx"},
-										&ast.Ident{Name: "network"},
-										&ast.Ident{Name: "address"},
-									},
-								},
-							},
-						},
-					},
-				}
-				foundDialContext = true
-			case funcName == "Listen" && funcDecl.Recv == nil:
-				// replace function body of Listen function.
-				//
-				// func Listen(network, address string) (Listener, error) {
-				//   return _listen(network, address)
-				// }
-				funcDecl.Body = &ast.BlockStmt{
-					List: []ast.Stmt{
-						&ast.ReturnStmt{
-							Results: []ast.Expr{
-								&ast.CallExpr{
-									Fun: &ast.Ident{Name: "_listen"},
-									Args: []ast.Expr{
-										&ast.Ident{Name: "network"},
-										&ast.Ident{Name: "address"},
-									},
-								},
-							},
-						},
-					},
-				}
-				foundListen = true
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		for i, rule := range rules {
+			if rule.Match(funcDecl) {
+				funcDecl.Body = rule.NewBody(funcDecl)
+				matched[i] = true
 			}
 		}
 	}
 
-	// Check if at least one target function was found
-	if !foundDialContext && !foundListen {
-		return nil, fmt.Errorf("no target functions (DialContext or Listen) found in %s", path)
+	foundAny := false
+	for _, ok := range matched {
+		if ok {
+			foundAny = true
+			break
+		}
 	}
-
-	// //go:linkname _dialContext github.com/goccy/wasi-go-net/wasip1.DialContext
-	// func _dialContext(ctx context.Context, network, address string) (Conn, error)
-	dialContextFuncDecl := &ast.FuncDecl{
-		Doc: &ast.CommentGroup{
-			List: []*ast.Comment{
-				{Text: "//go:linkname _dialContext github.com/goccy/wasi-go-net/wasip1.DialContext"},
-			},
-		},
-		Name: &ast.Ident{Name: "_dialContext"},
-		Type: &ast.FuncType{
-			Params: &ast.FieldList{
-				List: []*ast.Field{
-					{
-						Names: []*ast.Ident{{Name: "ctx"}},
-						Type:  &ast.SelectorExpr{X: &ast.Ident{Name: "context"}, Sel: &ast.Ident{Name: "Context"}},
-					},
-					{
-						Names: []*ast.Ident{{Name: "network"}},
-						Type:  &ast.Ident{Name: "string"},
-					},
-					{
-						Names: []*ast.Ident{{Name: "address"}},
-						Type:  &ast.Ident{Name: "string"},
-					},
-				},
-			},
-			Results: &ast.FieldList{
-				List: []*ast.Field{
-					{Type: &ast.Ident{Name: "Conn"}},
-					{Type: &ast.Ident{Name: "error"}},
-				},
-			},
-		},
-		Body: nil, // No body for external linkage
+	if !foundAny {
+		return nil, fmt.Errorf("no target functions found in %s", path)
 	}
 
-	// //go:linkname _listen github.com/goccy/wasi-go-net/wasip1.Listen
-	// func _listen(network, address string) (Listener, error)
-	listenFuncDecl := &ast.FuncDecl{
-		Doc: &ast.CommentGroup{
-			List: []*ast.Comment{
-				{Text: "//go:linkname _listen github.com/goccy/wasi-go-net/wasip1.Listen"},
-			},
-		},
-		Name: &ast.Ident{Name: "_listen"},
-		Type: &ast.FuncType{
-			Params: &ast.FieldList{
-				List: []*ast.Field{
-					{
-						Names: []*ast.Ident{{Name: "network"}},
-						Type:  &ast.Ident{Name: "string"},
-					},
-					{
-						Names: []*ast.Ident{{Name: "address"}},
-						Type:  &ast.Ident{Name: "string"},
-					},
-				},
-			},
-			Results: &ast.FieldList{
-				List: []*ast.Field{
-					{Type: &ast.Ident{Name: "Listener"}},
-					{Type: &ast.Ident{Name: "error"}},
-				},
-			},
-		},
-		Body: nil, // No body for external linkage
-	}
+	for i, rule := range rules {
+		if !matched[i] {
+			continue
+		}
 
-	if foundDialContext {
-		var lastPos token.Pos
-		if len(astFile.Decls) != 0 {
-			lastPos = astFile.Decls[len(astFile.Decls)-1].End()
+		shimDecl, err := rule.Shim.FuncDecl()
+		if err != nil {
+			return nil, err
 		}
 
-		dialContextFuncDecl.Type.Func = lastPos + 1
-		astFile.Decls = append(astFile.Decls, dialContextFuncDecl)
-	}
-	if foundListen {
 		var lastPos token.Pos
 		if len(astFile.Decls) != 0 {
 			lastPos = astFile.Decls[len(astFile.Decls)-1].End()
 		}
-
-		listenFuncDecl.Type.Func = lastPos + 1
-		astFile.Decls = append(astFile.Decls, listenFuncDecl)
+		shimDecl.Type.Func = lastPos + 1
+		astFile.Decls = append(astFile.Decls, shimDecl)
 	}
 
 	var buf bytes.Buffer
@@ -1,6 +1,7 @@
 package net
 
 import (
+	"go/ast"
 	"os"
 	"testing"
 
@@ -19,6 +20,86 @@ func TestCreateReplacedNetPkgOverlayFile(t *testing.T) {
 	}
 }
 
+func TestDefaultRules(t *testing.T) {
+	rules := DefaultRules()
+	if len(rules) != len(netRewriteRules) {
+		t.Fatalf("expected %d default rules, got %d", len(netRewriteRules), len(rules))
+	}
+	for i, rule := range rules {
+		if rule.Match == nil || rule.NewBody == nil || rule.Shim.Name == "" {
+			t.Fatalf("rule %d is missing a Match, NewBody, or Shim.Name", i)
+		}
+	}
+}
+
+func TestCreateReplacedNetSourceWithRules(t *testing.T) {
+	customRule := RewriteRule{
+		Match: func(decl *ast.FuncDecl) bool {
+			return decl.Name.Name == "LookupPort" && decl.Recv == nil
+		},
+		NewBody: func(decl *ast.FuncDecl) *ast.BlockStmt {
+			return &ast.BlockStmt{
+				List: []ast.Stmt{
+					&ast.ReturnStmt{
+						Results: []ast.Expr{
+							&ast.CallExpr{
+								Fun:  &ast.Ident{Name: "_lookupPort"},
+								Args: []ast.Expr{&ast.Ident{Name: "network"}, &ast.Ident{Name: "service"}},
+							},
+						},
+					},
+				},
+			}
+		},
+		Shim: ShimDecl{
+			Name:           "_lookupPort",
+			LinknameTarget: "github.com/goccy/wasi-go-net/wasip1.LookupPort",
+			Params: []Param{
+				{Name: "network", Type: "string"},
+				{Name: "service", Type: "string"},
+			},
+			Results: []Param{{Type: "int"}, {Type: "error"}},
+		},
+	}
+
+	source := `
+package net
+
+// LookupPort looks up the port for the given network and service.
+func LookupPort(network, service string) (int, error) {
+	// implementation here
+	return 0, nil
+}
+`
+	tmpFile, err := os.CreateTemp("", "")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(source); err != nil {
+		t.Fatalf("failed to write source code: %v", err)
+	}
+	tmpFile.Close()
+
+	replacedSrc, err := createReplacedNetSourceWithRules(tmpFile.Name(), []RewriteRule{customRule})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `package net
+
+import _ "unsafe"
+
+func LookupPort(network, service string) (int, error) { return _lookupPort(network, service) }
+//go:linkname _lookupPort github.com/goccy/wasi-go-net/wasip1.LookupPort
+func _lookupPort(network string, service string) (int, error)
+`
+	if diff := cmp.Diff(string(replacedSrc), expected); diff != "" {
+		t.Errorf("(-got, +want)\n%s", diff)
+	}
+}
+
 func TestCreateReplacedNetSource(t *testing.T) {
 	tests := map[string]struct {
 		source   string
@@ -286,6 +367,327 @@ type Conn interface{}
 
 //go:linkname _dialContext github.com/goccy/wasi-go-net/wasip1.DialContext
 func _dialContext(ctx context.Context, network string, address string) (Conn, error)
+`,
+		},
+
+		"fileConn, fileListener and filePacketConn": {
+			source: `
+package net
+
+import (
+	"os"
+)
+
+// FileConn returns a copy of the network connection corresponding to
+// the open file f.
+func FileConn(f *os.File) (c Conn, err error) {
+	// implementation here
+	return nil, nil
+}
+
+// FileListener returns a copy of the network listener corresponding
+// to the open file f.
+func FileListener(f *os.File) (ln Listener, err error) {
+	// implementation here
+	return nil, nil
+}
+
+// FilePacketConn returns a copy of the packet network connection
+// corresponding to the open file f.
+func FilePacketConn(f *os.File) (c PacketConn, err error) {
+	// implementation here
+	return nil, nil
+}
+
+type Conn interface{}
+type Listener interface{}
+type PacketConn interface{}
+`,
+			expected: `package net
+
+import (
+	"os"
+	_ "unsafe"
+)
+
+func FileConn(f *os.File) (c Conn, err error) { return _fileConn(f) }
+
+func FileListener(f *os.File) (ln Listener, err error) { return _fileListener(f) }
+
+func FilePacketConn(f *os.File) (c PacketConn, err error) { return _filePacketConn(f) }
+
+type Conn interface{}
+type Listener interface{}
+type PacketConn interface{}
+
+//go:linkname _fileConn github.com/goccy/wasi-go-net/wasip1.FileConn
+func _fileConn(f *os.File) (Conn, error)
+
+//go:linkname _fileListener github.com/goccy/wasi-go-net/wasip1.FileListener
+func _fileListener(f *os.File) (Listener, error)
+
+//go:linkname _filePacketConn github.com/goccy/wasi-go-net/wasip1.FilePacketConn
+func _filePacketConn(f *os.File) (PacketConn, error)
+`,
+		},
+		"dial and dialerDial": {
+			source: `
+package net
+
+import (
+	"syscall"
+)
+
+// Dial connects to the address on the named network.
+func Dial(network, address string) (Conn, error) {
+	var d Dialer
+	return d.Dial(network, address)
+}
+
+type Dialer struct{}
+
+func (d *Dialer) Dial(network, address string) (Conn, error) {
+	// implementation here
+	return nil, nil
+}
+
+type Conn interface{}
+`,
+			expected: `package net
+
+import (
+	"syscall"
+	_ "unsafe"
+)
+
+func Dial(network, address string) (Conn, error) { return _dial(network, address) }
+
+type Dialer struct{}
+
+func (d *Dialer) Dial(network, address string) (Conn, error) { return _dialerDial(network, address) }
+
+type Conn interface{}
+
+//go:linkname _dial github.com/goccy/wasi-go-net/wasip1.Dial
+func _dial(network string, address string) (Conn, error)
+
+//go:linkname _dialerDial github.com/goccy/wasi-go-net/wasip1.Dial
+func _dialerDial(network string, address string) (Conn, error)
+`,
+		},
+
+		"listenPacket and listenConfig.ListenPacket": {
+			source: `
+package net
+
+import (
+	"syscall"
+)
+
+// ListenPacket announces on the local network address.
+func ListenPacket(network, address string) (PacketConn, error) {
+	var lc ListenConfig
+	return lc.ListenPacket(nil, network, address)
+}
+
+type PacketConn interface{}
+type ListenConfig struct{}
+
+func (lc *ListenConfig) ListenPacket(ctx interface{}, network, address string) (PacketConn, error) {
+	// implementation here
+	return nil, nil
+}
+`,
+			expected: `package net
+
+import (
+	"syscall"
+	_ "unsafe"
+)
+
+func ListenPacket(network, address string) (PacketConn, error) {
+	return _listenPacket(network, address)
+}
+
+type PacketConn interface{}
+type ListenConfig struct{}
+
+func (lc *ListenConfig) ListenPacket(ctx interface{}, network, address string) (PacketConn, error) {
+	return _listenConfigListenPacket(lc, ctx, network, address)
+}
+
+//go:linkname _listenPacket github.com/goccy/wasi-go-net/wasip1.ListenPacket
+func _listenPacket(network string, address string) (PacketConn, error)
+
+//go:linkname _listenConfigListenPacket github.com/goccy/wasi-go-net/wasip1.ListenConfigListenPacket
+func _listenConfigListenPacket(recv *ListenConfig, ctx context.Context, network string, address string) (PacketConn, error)
+`,
+		},
+
+		"listenConfig.Listen": {
+			source: `
+package net
+
+import (
+	"context"
+)
+
+type ListenConfig struct {
+	Control func(network, address string, c interface{}) error
+}
+
+// Listen announces on the local network address.
+func (lc *ListenConfig) Listen(ctx context.Context, network, address string) (Listener, error) {
+	// implementation here
+	return nil, nil
+}
+
+type Listener interface{}
+`,
+			expected: `package net
+
+import (
+	"context"
+	_ "unsafe"
+)
+
+type ListenConfig struct {
+	Control func(network, address string, c interface{}) error
+}
+
+func (lc *ListenConfig) Listen(ctx context.Context, network, address string) (Listener, error) {
+	return _listenConfigListen(lc, ctx, network, address)
+}
+
+type Listener interface{}
+
+//go:linkname _listenConfigListen github.com/goccy/wasi-go-net/wasip1.ListenConfigListen
+func _listenConfigListen(recv *ListenConfig, ctx context.Context, network string, address string) (Listener, error)
+`,
+		},
+		"resolver": {
+			source: `
+package net
+
+import (
+	"context"
+)
+
+type Resolver struct {
+	PreferGo bool
+}
+
+type IPAddr struct{}
+type SRV struct{}
+type MX struct{}
+type NS struct{}
+
+// LookupHost looks up the given host.
+func (r *Resolver) LookupHost(ctx context.Context, host string) (addrs []string, err error) {
+	// implementation here
+	return nil, nil
+}
+
+// LookupIPAddr looks up host using the resolver.
+func (r *Resolver) LookupIPAddr(ctx context.Context, host string) ([]IPAddr, error) {
+	// implementation here
+	return nil, nil
+}
+
+// LookupCNAME returns the canonical name for the given host.
+func (r *Resolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	// implementation here
+	return "", nil
+}
+
+// LookupSRV tries to resolve an SRV query.
+func (r *Resolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*SRV, error) {
+	// implementation here
+	return "", nil, nil
+}
+
+// LookupMX returns the DNS MX records for the given domain name.
+func (r *Resolver) LookupMX(ctx context.Context, name string) ([]*MX, error) {
+	// implementation here
+	return nil, nil
+}
+
+// LookupNS returns the DNS NS records for the given domain name.
+func (r *Resolver) LookupNS(ctx context.Context, name string) ([]*NS, error) {
+	// implementation here
+	return nil, nil
+}
+
+// LookupTXT returns the DNS TXT records for the given domain name.
+func (r *Resolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	// implementation here
+	return nil, nil
+}
+`,
+			expected: `package net
+
+import (
+	"context"
+	_ "unsafe"
+)
+
+type Resolver struct {
+	PreferGo bool
+}
+
+type IPAddr struct{}
+type SRV struct{}
+type MX struct{}
+type NS struct{}
+
+func (r *Resolver) LookupHost(ctx context.Context, host string) (addrs []string, err error) {
+	return _resolverLookupHost(r, ctx, host)
+}
+
+func (r *Resolver) LookupIPAddr(ctx context.Context, host string) ([]IPAddr, error) {
+	return _resolverLookupIPAddr(r, ctx, host)
+}
+
+func (r *Resolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	return _resolverLookupCNAME(r, ctx, host)
+}
+
+func (r *Resolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*SRV, error) {
+	return _resolverLookupSRV(r, ctx, service, proto, name)
+}
+
+func (r *Resolver) LookupMX(ctx context.Context, name string) ([]*MX, error) {
+	return _resolverLookupMX(r, ctx, name)
+}
+
+func (r *Resolver) LookupNS(ctx context.Context, name string) ([]*NS, error) {
+	return _resolverLookupNS(r, ctx, name)
+}
+
+func (r *Resolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return _resolverLookupTXT(r, ctx, name)
+}
+
+//go:linkname _resolverLookupHost github.com/goccy/wasi-go-net/wasip1.ResolverLookupHost
+func _resolverLookupHost(recv *Resolver, ctx context.Context, host string) ([]string, error)
+
+//go:linkname _resolverLookupIPAddr github.com/goccy/wasi-go-net/wasip1.ResolverLookupIPAddr
+func _resolverLookupIPAddr(recv *Resolver, ctx context.Context, host string) ([]IPAddr, error)
+
+//go:linkname _resolverLookupCNAME github.com/goccy/wasi-go-net/wasip1.ResolverLookupCNAME
+func _resolverLookupCNAME(recv *Resolver, ctx context.Context, host string) (string, error)
+
+//go:linkname _resolverLookupSRV github.com/goccy/wasi-go-net/wasip1.ResolverLookupSRV
+func _resolverLookupSRV(recv *Resolver, ctx context.Context, service string, proto string, name string) (string, []*SRV, error)
+
+//go:linkname _resolverLookupMX github.com/goccy/wasi-go-net/wasip1.ResolverLookupMX
+func _resolverLookupMX(recv *Resolver, ctx context.Context, name string) ([]*MX, error)
+
+//go:linkname _resolverLookupNS github.com/goccy/wasi-go-net/wasip1.ResolverLookupNS
+func _resolverLookupNS(recv *Resolver, ctx context.Context, name string) ([]*NS, error)
+
+//go:linkname _resolverLookupTXT github.com/goccy/wasi-go-net/wasip1.ResolverLookupTXT
+func _resolverLookupTXT(recv *Resolver, ctx context.Context, name string) ([]string, error)
 `,
 		},
 	}
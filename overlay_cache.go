@@ -0,0 +1,174 @@
+package net
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+)
+
+// CreateReplacedNetPkgOverlayFileCached is CreateReplacedNetPkgOverlayFile, but backed by an
+// on-disk cache keyed by the Go version, this module's version, and the net package source
+// being rewritten. Repeated invocations with unchanged inputs reuse the previously rewritten
+// files and overlay JSON instead of re-parsing $GOROOT/src/net and writing fresh temp files each
+// time, which both speeds up repeated `go build` invocations and lets the Go build cache work
+// across them (the overlay file's path, and therefore the replaced packages' content hash, stays
+// stable). If cacheDir is empty, os.UserCacheDir()/wasi-go-net is used.
+func CreateReplacedNetPkgOverlayFileCached(ctx context.Context, cacheDir string) (*OverlayFile, error) {
+	return CreateReplacedNetPkgOverlayFileCachedWithRules(ctx, cacheDir, DefaultRules()...)
+}
+
+// CreateReplacedNetPkgOverlayFileCachedWithRules is CreateReplacedNetPkgOverlayFileCached
+// parameterized over rules instead of always applying DefaultRules.
+func CreateReplacedNetPkgOverlayFileCachedWithRules(ctx context.Context, cacheDir string, rules ...RewriteRule) (*OverlayFile, error) {
+	if cacheDir == "" {
+		dir, err := defaultCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine default cache dir: %w", err)
+		}
+		cacheDir = dir
+	}
+
+	netPkgFiles, err := netPkgGoFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := overlayCacheDigest(netPkgFiles, rules)
+	if err != nil {
+		return nil, err
+	}
+	digestDir := filepath.Join(cacheDir, digest)
+	overlayPath := filepath.Join(digestDir, "overlay.json")
+
+	if _, err := os.Stat(overlayPath); err == nil {
+		return &OverlayFile{path: overlayPath, cached: true}, nil
+	}
+
+	srcs, err := GetReplacedNetSourcesWithRules(ctx, rules...)
+	if err != nil {
+		return nil, err
+	}
+	return createOverlayFileCached(digestDir, overlayPath, srcs)
+}
+
+// overlayCacheDigest hashes the Go toolchain version, this module's version, the content of
+// every net package source file under consideration, and the rules being applied into a stable,
+// content-addressed digest.
+func overlayCacheDigest(netPkgFiles []string, rules []RewriteRule) (string, error) {
+	h := sha256.New()
+	fmt.Fprintln(h, runtime.Version())
+	fmt.Fprintln(h, moduleVersion())
+	for _, rule := range rules {
+		// Match and NewBody are funcs and can't be hashed directly, but every built-in and
+		// reasonable custom rule is identified by its Shim: two rules with the same shim name,
+		// link target, and signature are the same rewrite for caching purposes.
+		fmt.Fprintln(h, rule.Shim.Name, rule.Shim.LinknameTarget)
+		for _, p := range rule.Shim.Params {
+			fmt.Fprintln(h, "param", p.Name, p.Type)
+		}
+		for _, p := range rule.Shim.Results {
+			fmt.Fprintln(h, "result", p.Name, p.Type)
+		}
+	}
+
+	sortedPaths := append([]string(nil), netPkgFiles...)
+	sort.Strings(sortedPaths)
+	for _, path := range sortedPaths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file %s: %w", path, err)
+		}
+		fmt.Fprintln(h, path)
+		h.Write(content)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// modulePath is this module's own import path, used to find its version in a downstream
+// binary's build info.
+const modulePath = "github.com/goccy/wasi-go-net"
+
+// moduleVersion reports this module's version as recorded in the running binary's build info,
+// falling back to "devel" when that information isn't available (e.g. `go run` of a local
+// checkout without a pinned version). When wasi-go-net is used as a library - the intended use -
+// it's a dependency of the binary doing the reading, not the main module, so its version has to
+// be looked up in info.Deps rather than taken from info.Main; otherwise bumping wasi-go-net's own
+// version without also bumping the importing binary's version would never bust the cache.
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			return dep.Version
+		}
+	}
+	return "devel"
+}
+
+// defaultCacheDir is cacheDir/wasi-go-net under the user's standard cache directory.
+func defaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "wasi-go-net"), nil
+}
+
+// createOverlayFileCached writes srcs and the overlay JSON mapping into digestDir, returning an
+// *OverlayFile whose Close is a no-op: the cache, not the caller, owns the lifetime of these
+// files.
+func createOverlayFileCached(digestDir, overlayPath string, srcs []*ReplacedNetSource) (*OverlayFile, error) {
+	if err := os.MkdirAll(digestDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir %s: %w", digestDir, err)
+	}
+
+	overlayMap := make(map[string]string, len(srcs))
+	for i, src := range srcs {
+		cachedPath := filepath.Join(digestDir, fmt.Sprintf("%03d_%s", i, filepath.Base(src.Path)))
+		if err := os.WriteFile(cachedPath, src.Content, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write cached file %s: %w", cachedPath, err)
+		}
+		overlayMap[src.Path] = cachedPath
+	}
+
+	content, err := json.Marshal(map[string]interface{}{
+		"Replace": overlayMap,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create overlay file content: %w", err)
+	}
+
+	// Write to a temp file in the same directory and rename into place, so concurrent cache
+	// misses for the same digest never observe a partially written overlay.json.
+	tmpFile, err := os.CreateTemp(digestDir, "overlay.json.tmp_")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		return nil, fmt.Errorf("failed to write file content: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpFile.Name())
+		return nil, fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), overlayPath); err != nil {
+		os.Remove(tmpFile.Name())
+		return nil, fmt.Errorf("failed to rename overlay file into place: %w", err)
+	}
+
+	return &OverlayFile{path: overlayPath, cached: true}, nil
+}
@@ -0,0 +1,35 @@
+package net
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCreateReplacedNetPkgOverlayFileCached(t *testing.T) {
+	cacheDir, err := os.MkdirTemp("", "wasi-go-net-cache")
+	if err != nil {
+		t.Fatalf("failed to create temp cache dir: %v", err)
+	}
+	defer os.RemoveAll(cacheDir)
+
+	f, err := CreateReplacedNetPkgOverlayFileCached(t.Context(), cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := f.Path()
+	f.Close()
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected cached overlay file to survive Close: %v", err)
+	}
+
+	cached, err := CreateReplacedNetPkgOverlayFileCached(t.Context(), cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cached.Close()
+
+	if cached.Path() != path {
+		t.Fatalf("expected cache hit to reuse overlay path %s, got %s", path, cached.Path())
+	}
+}
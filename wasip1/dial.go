@@ -0,0 +1,259 @@
+//go:build wasip1
+
+package wasip1
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// connectPollInterval is how often connectNonblock re-checks a non-blocking connect that
+// hasn't completed yet. WASI preview1 sockets have no poller to hook into (see rawConn in
+// listen_config.go), so waiting for connect to finish means polling rather than blocking on
+// an event.
+const connectPollInterval = 5 * time.Millisecond
+
+// connectTimeout bounds how long connectNonblock will poll before giving up on a connect that
+// never completes.
+const connectTimeout = 30 * time.Second
+
+// connectNonblock connects fd, which must already be in non-blocking mode, to sa, giving up
+// early if ctx is done. A non-blocking connect to a non-loopback address normally returns
+// EINPROGRESS immediately; per connect(2), the standard way to learn the outcome without a
+// poller is to retry the same connect call, which returns EALREADY while still pending,
+// EISCONN once it has succeeded, or the real error if it failed.
+func connectNonblock(ctx context.Context, fd int, sa syscall.Sockaddr) error {
+	err := syscall.Connect(fd, sa)
+	if err == nil || err == syscall.EISCONN {
+		return nil
+	}
+	if err != syscall.EINPROGRESS {
+		return err
+	}
+
+	deadline := time.Now().Add(connectTimeout)
+	timer := time.NewTimer(connectPollInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+		switch err := syscall.Connect(fd, sa); err {
+		case nil, syscall.EISCONN:
+			return nil
+		case syscall.EALREADY, syscall.EINPROGRESS:
+			if time.Now().After(deadline) {
+				return syscall.ETIMEDOUT
+			}
+			timer.Reset(connectPollInterval)
+		default:
+			return err
+		}
+	}
+}
+
+// Dial is the link target for net.Dial and (*net.Dialer).Dial. It is DialContext with
+// context.Background(), mirroring how net.Dial itself forwards to DialContext.
+func Dial(network, address string) (net.Conn, error) {
+	return DialContext(context.Background(), network, address)
+}
+
+// DialContext is the link target for net.DialContext and (*net.Dialer).DialContext. It creates
+// a new WASI socket and connects it to address over network, honoring ctx's cancellation and
+// deadline while the connect is in progress. Dialer-specific fields such as Timeout are not
+// honored here: the rewrite rule backing this shim does not forward the Dialer receiver, only
+// its DialContext arguments.
+func DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	family, sotype, err := socketParams(network)
+	if err != nil {
+		return nil, err
+	}
+	raddr, sa, err := resolveSockaddr(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := syscall.Socket(family, sotype, 0)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("setnonblock", err)
+	}
+	if err := connectNonblock(ctx, fd, sa); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("connect", err)
+	}
+	return newConn(fd, nil, raddr)
+}
+
+// Listen is the link target for net.Listen. It creates a new WASI socket, binds it to address
+// over network, and puts it into the listening state.
+func Listen(network, address string) (net.Listener, error) {
+	family, sotype, err := socketParams(network)
+	if err != nil {
+		return nil, err
+	}
+	laddr, sa, err := resolveSockaddr(context.Background(), network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := syscall.Socket(family, sotype, 0)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("setnonblock", err)
+	}
+	if err := syscall.Bind(fd, sa); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("bind", err)
+	}
+	if err := syscall.Listen(fd, maxListenBacklog); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("listen", err)
+	}
+	return newListener(fd, laddr)
+}
+
+// ListenPacket is the link target for net.ListenPacket. It creates a new WASI datagram socket
+// and binds it to address over network.
+func ListenPacket(network, address string) (net.PacketConn, error) {
+	family, sotype, err := socketParams(network)
+	if err != nil {
+		return nil, err
+	}
+	laddr, sa, err := resolveSockaddr(context.Background(), network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := syscall.Socket(family, sotype, 0)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("setnonblock", err)
+	}
+	if err := syscall.Bind(fd, sa); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("bind", err)
+	}
+	return newPacketConn(fd, laddr)
+}
+
+// socketParams maps a Go network name to the address family and socket type used to create
+// the underlying WASI socket.
+func socketParams(network string) (family, sotype int, err error) {
+	switch network {
+	case "tcp", "tcp4":
+		return syscall.AF_INET, syscall.SOCK_STREAM, nil
+	case "tcp6":
+		return syscall.AF_INET6, syscall.SOCK_STREAM, nil
+	case "udp", "udp4":
+		return syscall.AF_INET, syscall.SOCK_DGRAM, nil
+	case "udp6":
+		return syscall.AF_INET6, syscall.SOCK_DGRAM, nil
+	default:
+		return 0, 0, fmt.Errorf("wasip1: unsupported network %q", network)
+	}
+}
+
+// resolveSockaddr resolves address on network into both a net.Addr for bookkeeping and the raw
+// syscall.Sockaddr required to connect or bind a socket. Unlike net.ResolveTCPAddr/ResolveUDPAddr,
+// which send any non-literal host through (*net.Resolver).lookupIPAddr - the original, unrewritten
+// stdlib resolver - this resolves hostnames itself via DefaultResolver, so that Dial/Listen/
+// ListenPacket actually benefit from the DoH/DoT resolver built for this package instead of
+// falling back to a resolver WASI has no way to run.
+func resolveSockaddr(ctx context.Context, network, address string) (net.Addr, syscall.Sockaddr, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, nil, &net.AddrError{Err: "unknown port", Addr: address}
+	}
+
+	ip, zone, err := resolveHost(ctx, network, host)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var addr net.Addr
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		addr = &net.TCPAddr{IP: ip, Port: port, Zone: zone}
+	case "udp", "udp4", "udp6":
+		addr = &net.UDPAddr{IP: ip, Port: port, Zone: zone}
+	default:
+		return nil, nil, fmt.Errorf("wasip1: unsupported network %q", network)
+	}
+
+	sa, err := addrToSockaddr(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return addr, sa, nil
+}
+
+// resolveHost turns the host half of a Dial/Listen address into an IP, honoring network's
+// address-family suffix (tcp4/tcp6/udp4/udp6). An empty host resolves to the IPv6 wildcard
+// address for tcp6/udp6 and to the IPv4 wildcard address otherwise (the families socketParams
+// picks), matching net.ResolveTCPAddr/ResolveUDPAddr's handling of a missing host in a listen
+// address.
+func resolveHost(ctx context.Context, network, host string) (net.IP, string, error) {
+	if host == "" {
+		if strings.HasSuffix(network, "6") {
+			return net.IPv6unspecified, "", nil
+		}
+		return net.IPv4zero, "", nil
+	}
+
+	host, zone := splitHostZone(host)
+	if ip := net.ParseIP(host); ip != nil {
+		return ip, zone, nil
+	}
+
+	wantIPv6 := strings.HasSuffix(network, "6")
+	wantIPv4 := strings.HasSuffix(network, "4")
+
+	addrs, err := DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, addr := range addrs {
+		is4 := addr.IP.To4() != nil
+		if wantIPv6 && is4 {
+			continue
+		}
+		if wantIPv4 && !is4 {
+			continue
+		}
+		return addr.IP, addr.Zone, nil
+	}
+	return nil, "", &net.AddrError{Err: "no suitable address found", Addr: host}
+}
+
+// splitHostZone splits a literal IPv6 host of the form "address%zone" into its address and zone,
+// mirroring the net package's internal helper of the same name. Hosts without a "%" are returned
+// unchanged with an empty zone.
+func splitHostZone(host string) (string, string) {
+	i := strings.LastIndexByte(host, '%')
+	if i < 0 {
+		return host, ""
+	}
+	return host[:i], host[i+1:]
+}
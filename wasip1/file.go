@@ -0,0 +1,59 @@
+//go:build wasip1
+
+package wasip1
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// FileConn is the link target for net.FileConn. f is expected to wrap a
+// WASI preopened or host-imported socket descriptor; FileConn dup's it,
+// puts the duplicate into non-blocking mode and wraps it in the net.Conn
+// implementation used throughout this package. The original f is left
+// open and still owned by the caller.
+func FileConn(f *os.File) (c net.Conn, err error) {
+	fd, err := dupNonblock(f)
+	if err != nil {
+		return nil, err
+	}
+	nc, err := newConn(fd, nil, nil)
+	if err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	return nc, nil
+}
+
+// FileListener is the link target for net.FileListener. It behaves like
+// FileConn but wraps the duplicated descriptor in a net.Listener,
+// assuming f already refers to a socket in the listening state.
+func FileListener(f *os.File) (ln net.Listener, err error) {
+	fd, err := dupNonblock(f)
+	if err != nil {
+		return nil, err
+	}
+	l, err := newListener(fd, nil)
+	if err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	return l, nil
+}
+
+// FilePacketConn is the link target for net.FilePacketConn. It behaves
+// like FileConn but wraps the duplicated descriptor in a net.PacketConn,
+// assuming f already refers to a datagram socket.
+func FilePacketConn(f *os.File) (c net.PacketConn, err error) {
+	fd, err := dupNonblock(f)
+	if err != nil {
+		return nil, err
+	}
+	pc, err := newPacketConn(fd, nil)
+	if err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	return pc, nil
+}
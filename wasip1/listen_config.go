@@ -0,0 +1,129 @@
+//go:build wasip1
+
+package wasip1
+
+import (
+	"context"
+	"net"
+	"os"
+	"syscall"
+)
+
+// maxListenBacklog is the backlog passed to syscall.Listen for sockets opened through this
+// package; WASI preview1 sockets have no SOMAXCONN-style tunable to read it back from.
+const maxListenBacklog = 128
+
+// solSocket and soKeepAlive follow the BSD-style sockopt numbering used by wasi-libc's socket
+// extension, which the rest of this package's syscall bindings also assume.
+const (
+	solSocket   = 0xffff
+	soKeepAlive = 0x0008
+)
+
+// ListenConfigListen is the link target for (*net.ListenConfig).Listen. recv is the original
+// receiver, forwarded so Control, KeepAlive and KeepAliveConfig can be honored.
+func ListenConfigListen(recv *net.ListenConfig, ctx context.Context, network, address string) (net.Listener, error) {
+	family, sotype, err := socketParams(network)
+	if err != nil {
+		return nil, err
+	}
+	laddr, sa, err := resolveSockaddr(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := syscall.Socket(family, sotype, 0)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	if err := applyListenConfig(recv, fd, network, address); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("setnonblock", err)
+	}
+	if err := syscall.Bind(fd, sa); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("bind", err)
+	}
+	if err := syscall.Listen(fd, maxListenBacklog); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("listen", err)
+	}
+	return newListener(fd, laddr)
+}
+
+// ListenConfigListenPacket is the link target for (*net.ListenConfig).ListenPacket.
+func ListenConfigListenPacket(recv *net.ListenConfig, ctx context.Context, network, address string) (net.PacketConn, error) {
+	family, sotype, err := socketParams(network)
+	if err != nil {
+		return nil, err
+	}
+	laddr, sa, err := resolveSockaddr(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := syscall.Socket(family, sotype, 0)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	if err := applyListenConfig(recv, fd, network, address); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("setnonblock", err)
+	}
+	if err := syscall.Bind(fd, sa); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("bind", err)
+	}
+	return newPacketConn(fd, laddr)
+}
+
+// applyListenConfig honors the subset of net.ListenConfig that can be mapped onto a WASI
+// socket: it runs Control (if set) before the socket is bound, and enables SO_KEEPALIVE unless
+// KeepAlive explicitly asks for it to be disabled. This mirrors net.ListenConfig.KeepAlive's
+// documented semantics: a negative value disables keep-alive, while zero (the default for a
+// freshly-constructed ListenConfig) and any positive value both enable it.
+//
+// lc.KeepAliveConfig.Enable is only defined starting with Go 1.23; this module's go.mod pins
+// go 1.24 (also required by this series' use of testing.T.Context), so it is always available
+// at the Go version this module builds with.
+func applyListenConfig(lc *net.ListenConfig, fd int, network, address string) error {
+	if lc == nil {
+		return nil
+	}
+	if lc.Control != nil {
+		if err := lc.Control(network, address, rawConn{fd: fd}); err != nil {
+			return err
+		}
+	}
+	if lc.KeepAlive >= 0 || lc.KeepAliveConfig.Enable {
+		if err := syscall.SetsockoptInt(fd, solSocket, soKeepAlive, 1); err != nil {
+			return os.NewSyscallError("setsockopt", err)
+		}
+	}
+	return nil
+}
+
+// rawConn is the minimal syscall.RawConn implementation passed to net.ListenConfig.Control.
+// WASI preview1 sockets have no poller to hook into, so Read and Write are not supported.
+type rawConn struct{ fd int }
+
+func (c rawConn) Control(f func(fd uintptr)) error {
+	f(uintptr(c.fd))
+	return nil
+}
+
+func (c rawConn) Read(f func(fd uintptr) bool) error {
+	return syscall.ENOSYS
+}
+
+func (c rawConn) Write(f func(fd uintptr) bool) error {
+	return syscall.ENOSYS
+}
@@ -0,0 +1,344 @@
+//go:build wasip1
+
+package wasip1
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// resourceRecord is the cache's view of a single answer record: enough to reconstruct whichever
+// net.* lookup result it backs without keeping the wire-format dnsmessage.Resource around.
+type resourceRecord struct {
+	name dnsmessage.Name
+	ttl  uint32
+	body dnsmessage.ResourceBody
+}
+
+// Transport exchanges a single raw DNS message for its response. Resolver uses it as the
+// pluggable mechanism for actually reaching a recursive resolver from inside WASI, where there
+// is no getaddrinfo/res_query to fall back on.
+type Transport interface {
+	Exchange(ctx context.Context, query []byte) ([]byte, error)
+}
+
+// Resolver is the WASI replacement for net.Resolver. It has no access to /etc/resolv.conf or the
+// host's resolver library, so every lookup is performed itself, over Transport.
+type Resolver struct {
+	// Transport performs the actual DNS exchange. If nil, a DNS-over-HTTPS transport pointed at
+	// DefaultDoHServer is used.
+	Transport Transport
+	// PolicyTable drives RFC 6724 destination address selection. If nil, defaultPolicyTable is
+	// used.
+	PolicyTable []policyEntry
+
+	cache *answerCache
+}
+
+// DefaultDoHServer is the DNS-over-HTTPS endpoint used when a Resolver has no Transport set.
+// It is a literal IP address rather than a hostname on purpose: resolving any hostname, this
+// one included, goes through DefaultResolver, so pointing the bootstrap transport at a name
+// would recurse into itself on the very first lookup.
+const DefaultDoHServer = "https://1.1.1.1/dns-query"
+
+// NewResolver returns a Resolver configured with the default DNS-over-HTTPS transport, the
+// default RFC 6724 policy table, and answer caching enabled.
+func NewResolver() *Resolver {
+	return &Resolver{
+		Transport: &dohTransport{URL: DefaultDoHServer},
+		cache:     newAnswerCache(),
+	}
+}
+
+// DefaultResolver is the Resolver used by the ResolverLookup* link targets below, which is to
+// say the Resolver that net.DefaultResolver's rewritten methods forward to.
+var DefaultResolver = NewResolver()
+
+func (r *Resolver) transport() Transport {
+	if r.Transport != nil {
+		return r.Transport
+	}
+	return DefaultResolver.Transport
+}
+
+func (r *Resolver) policyTable() []policyEntry {
+	if r.PolicyTable != nil {
+		return r.PolicyTable
+	}
+	return defaultPolicyTable
+}
+
+func (r *Resolver) answerCache() *answerCache {
+	if r.cache != nil {
+		return r.cache
+	}
+	return DefaultResolver.cache
+}
+
+// lookup resolves name for qtype, consulting the cache first and populating it from the
+// transport's response otherwise.
+func (r *Resolver) lookup(ctx context.Context, name string, qtype dnsmessage.Type) ([]resourceRecord, error) {
+	fqdn := dnsmessage.MustNewName(toFQDN(name))
+
+	if rrs, err, ok := r.answerCache().get(name, uint16(qtype)); ok {
+		return rrs, err
+	}
+
+	query := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:               uint16(rand.Intn(1 << 16)),
+			RecursionDesired: true,
+		},
+		Questions: []dnsmessage.Question{
+			{Name: fqdn, Type: qtype, Class: dnsmessage.ClassINET},
+		},
+	}
+	wire, err := query.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("wasip1: failed to build DNS query: %w", err)
+	}
+
+	respWire, err := r.transport().Exchange(ctx, wire)
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: name, IsTemporary: true}
+	}
+
+	var resp dnsmessage.Message
+	if err := resp.Unpack(respWire); err != nil {
+		return nil, fmt.Errorf("wasip1: failed to parse DNS response for %q: %w", name, err)
+	}
+
+	if resp.RCode == dnsmessage.RCodeNameError {
+		notFoundErr := &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+		r.answerCache().put(name, uint16(qtype), nil, notFoundErr, 0)
+		return nil, notFoundErr
+	}
+	if resp.RCode != dnsmessage.RCodeSuccess {
+		return nil, &net.DNSError{Err: fmt.Sprintf("server returned %v", resp.RCode), Name: name}
+	}
+
+	var rrs []resourceRecord
+	var minTTL uint32
+	haveTTL := false
+	for _, a := range resp.Answers {
+		if a.Header.Type != qtype {
+			continue
+		}
+		rrs = append(rrs, resourceRecord{name: a.Header.Name, ttl: a.Header.TTL, body: a.Body})
+		if !haveTTL || a.Header.TTL < minTTL {
+			minTTL = a.Header.TTL
+			haveTTL = true
+		}
+	}
+
+	if len(rrs) == 0 {
+		notFoundErr := &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+		r.answerCache().put(name, uint16(qtype), nil, notFoundErr, 0)
+		return nil, notFoundErr
+	}
+
+	ttl := time.Duration(minTTL) * time.Second
+	r.answerCache().put(name, uint16(qtype), rrs, nil, ttl)
+	return rrs, nil
+}
+
+// toFQDN appends the trailing dot dnsmessage.NewName requires, if the caller didn't already
+// supply one.
+func toFQDN(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// LookupHost looks up the given host using the resolver. It returns a slice of that host's
+// addresses, selected and ordered per RFC 6724.
+func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	addrs, err := r.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, len(addrs))
+	for i, addr := range addrs {
+		hosts[i] = addr.String()
+	}
+	return hosts, nil
+}
+
+// LookupIPAddr looks up host using the resolver, returning its A and AAAA records sorted by
+// RFC 6724 destination address selection.
+func (r *Resolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	aRRs, aErr := r.lookup(ctx, host, dnsmessage.TypeA)
+	aaaaRRs, aaaaErr := r.lookup(ctx, host, dnsmessage.TypeAAAA)
+	if aErr != nil && aaaaErr != nil {
+		return nil, aErr
+	}
+
+	addrs := make([]netip.Addr, 0, len(aRRs)+len(aaaaRRs))
+	for _, rr := range aRRs {
+		if body, ok := rr.body.(*dnsmessage.AResource); ok {
+			addrs = append(addrs, netip.AddrFrom4(body.A))
+		}
+	}
+	for _, rr := range aaaaRRs {
+		if body, ok := rr.body.(*dnsmessage.AAAAResource); ok {
+			addrs = append(addrs, netip.AddrFrom16(body.AAAA))
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	sortAddrs(r.policyTable(), addrs)
+
+	ipAddrs := make([]net.IPAddr, len(addrs))
+	for i, addr := range addrs {
+		ipAddrs[i] = net.IPAddr{IP: net.IP(addr.AsSlice())}
+	}
+	return ipAddrs, nil
+}
+
+// LookupCNAME returns the canonical name for the given host.
+func (r *Resolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	rrs, err := r.lookup(ctx, host, dnsmessage.TypeCNAME)
+	if err != nil {
+		return "", err
+	}
+	body, ok := rrs[0].body.(*dnsmessage.CNAMEResource)
+	if !ok {
+		return "", &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return body.CNAME.String(), nil
+}
+
+// LookupSRV tries to resolve an SRV query of the given service, protocol, and domain name.
+func (r *Resolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	target := name
+	if service != "" || proto != "" {
+		target = fmt.Sprintf("_%s._%s.%s", service, proto, name)
+	}
+	rrs, err := r.lookup(ctx, target, dnsmessage.TypeSRV)
+	if err != nil {
+		return "", nil, err
+	}
+	srvs := make([]*net.SRV, 0, len(rrs))
+	for _, rr := range rrs {
+		body, ok := rr.body.(*dnsmessage.SRVResource)
+		if !ok {
+			continue
+		}
+		srvs = append(srvs, &net.SRV{
+			Target:   body.Target.String(),
+			Port:     body.Port,
+			Priority: body.Priority,
+			Weight:   body.Weight,
+		})
+	}
+	byPriorityWeight(srvs).sort()
+	return target, srvs, nil
+}
+
+// LookupMX returns the DNS MX records for the given domain name sorted by preference.
+func (r *Resolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	rrs, err := r.lookup(ctx, name, dnsmessage.TypeMX)
+	if err != nil {
+		return nil, err
+	}
+	mxs := make([]*net.MX, 0, len(rrs))
+	for _, rr := range rrs {
+		body, ok := rr.body.(*dnsmessage.MXResource)
+		if !ok {
+			continue
+		}
+		mxs = append(mxs, &net.MX{Host: body.MX.String(), Pref: body.Pref})
+	}
+	byPref(mxs).sort()
+	return mxs, nil
+}
+
+// LookupNS returns the DNS NS records for the given domain name.
+func (r *Resolver) LookupNS(ctx context.Context, name string) ([]*net.NS, error) {
+	rrs, err := r.lookup(ctx, name, dnsmessage.TypeNS)
+	if err != nil {
+		return nil, err
+	}
+	nss := make([]*net.NS, 0, len(rrs))
+	for _, rr := range rrs {
+		body, ok := rr.body.(*dnsmessage.NSResource)
+		if !ok {
+			continue
+		}
+		nss = append(nss, &net.NS{Host: body.NS.String()})
+	}
+	return nss, nil
+}
+
+// LookupTXT returns the DNS TXT records for the given domain name.
+func (r *Resolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	rrs, err := r.lookup(ctx, name, dnsmessage.TypeTXT)
+	if err != nil {
+		return nil, err
+	}
+	var txts []string
+	for _, rr := range rrs {
+		body, ok := rr.body.(*dnsmessage.TXTResource)
+		if !ok {
+			continue
+		}
+		txts = append(txts, body.TXT...)
+	}
+	return txts, nil
+}
+
+// ResolverLookupHost is the link target for (*net.Resolver).LookupHost. recv is forwarded
+// because the rewrite rule that targets net.Resolver methods passes the receiver along, but
+// it is otherwise unused here: every lookup goes through DefaultResolver regardless of recv's
+// PreferGo, Dial, StrictErrors, or any other per-Resolver configuration, so a caller-configured
+// *net.Resolver is indistinguishable from the zero value. The other ResolverLookup* functions
+// below share this limitation.
+func ResolverLookupHost(recv *net.Resolver, ctx context.Context, host string) ([]string, error) {
+	return DefaultResolver.LookupHost(ctx, host)
+}
+
+// ResolverLookupIPAddr is the link target for (*net.Resolver).LookupIPAddr. recv's
+// configuration is ignored; see ResolverLookupHost.
+func ResolverLookupIPAddr(recv *net.Resolver, ctx context.Context, host string) ([]net.IPAddr, error) {
+	return DefaultResolver.LookupIPAddr(ctx, host)
+}
+
+// ResolverLookupCNAME is the link target for (*net.Resolver).LookupCNAME. recv's
+// configuration is ignored; see ResolverLookupHost.
+func ResolverLookupCNAME(recv *net.Resolver, ctx context.Context, host string) (string, error) {
+	return DefaultResolver.LookupCNAME(ctx, host)
+}
+
+// ResolverLookupSRV is the link target for (*net.Resolver).LookupSRV. recv's
+// configuration is ignored; see ResolverLookupHost.
+func ResolverLookupSRV(recv *net.Resolver, ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	return DefaultResolver.LookupSRV(ctx, service, proto, name)
+}
+
+// ResolverLookupMX is the link target for (*net.Resolver).LookupMX. recv's
+// configuration is ignored; see ResolverLookupHost.
+func ResolverLookupMX(recv *net.Resolver, ctx context.Context, name string) ([]*net.MX, error) {
+	return DefaultResolver.LookupMX(ctx, name)
+}
+
+// ResolverLookupNS is the link target for (*net.Resolver).LookupNS. recv's
+// configuration is ignored; see ResolverLookupHost.
+func ResolverLookupNS(recv *net.Resolver, ctx context.Context, name string) ([]*net.NS, error) {
+	return DefaultResolver.LookupNS(ctx, name)
+}
+
+// ResolverLookupTXT is the link target for (*net.Resolver).LookupTXT. recv's
+// configuration is ignored; see ResolverLookupHost.
+func ResolverLookupTXT(recv *net.Resolver, ctx context.Context, name string) ([]string, error) {
+	return DefaultResolver.LookupTXT(ctx, name)
+}
@@ -0,0 +1,77 @@
+//go:build wasip1
+
+package wasip1
+
+import (
+	"sync"
+	"time"
+)
+
+// answerCacheKey identifies a single cached question.
+type answerCacheKey struct {
+	name  string
+	qtype uint16
+}
+
+// answerCacheEntry holds a cached answer (positive or negative) along with the absolute time
+// it stops being valid, taken from the answer's TTL.
+type answerCacheEntry struct {
+	rrs     []resourceRecord
+	err     error
+	expires time.Time
+}
+
+// answerCache is a TTL-aware positive/negative answer cache shared by all lookups performed by
+// a Resolver. A nil *answerCache is valid and simply disables caching.
+type answerCache struct {
+	mu      sync.Mutex
+	entries map[answerCacheKey]answerCacheEntry
+	now     func() time.Time
+}
+
+func newAnswerCache() *answerCache {
+	return &answerCache{
+		entries: make(map[answerCacheKey]answerCacheEntry),
+		now:     time.Now,
+	}
+}
+
+func (c *answerCache) get(name string, qtype uint16) ([]resourceRecord, error, bool) {
+	if c == nil {
+		return nil, nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[answerCacheKey{name, qtype}]
+	if !ok || c.now().After(entry.expires) {
+		return nil, nil, false
+	}
+	return entry.rrs, entry.err, true
+}
+
+// put records rrs/err as the answer for name/qtype, valid for ttl. A negative answer (err set,
+// rrs empty) is cached too, so repeated lookups of names that don't exist don't re-query; since
+// negative responses carry no TTL of their own, they fall back to minNegativeTTL. A positive
+// answer with ttl <= 0 (DNS TTL 0 means "don't cache me") is not cached at all.
+func (c *answerCache) put(name string, qtype uint16, rrs []resourceRecord, err error, ttl time.Duration) {
+	if c == nil {
+		return
+	}
+	if ttl <= 0 {
+		if err == nil {
+			return
+		}
+		ttl = minNegativeTTL
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[answerCacheKey{name, qtype}] = answerCacheEntry{
+		rrs:     rrs,
+		err:     err,
+		expires: c.now().Add(ttl),
+	}
+}
+
+// minNegativeTTL bounds how long a negative (NXDOMAIN/no-answer) response is cached when the
+// upstream response carries no usable TTL of its own.
+const minNegativeTTL = 5 * time.Second
@@ -0,0 +1,75 @@
+//go:build wasip1
+
+package wasip1
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestAnswerCacheExpiresByTTL(t *testing.T) {
+	now := time.Now()
+	c := newAnswerCache()
+	c.now = func() time.Time { return now }
+
+	rrs := []resourceRecord{{}}
+	c.put("example.com.", 1, rrs, nil, 30*time.Second)
+
+	if _, _, ok := c.get("example.com.", 1); !ok {
+		t.Fatalf("expected cache hit before TTL elapses")
+	}
+
+	now = now.Add(31 * time.Second)
+	if _, _, ok := c.get("example.com.", 1); ok {
+		t.Fatalf("expected cache miss once TTL has elapsed")
+	}
+}
+
+func TestAnswerCacheNegativeUsesMinTTL(t *testing.T) {
+	now := time.Now()
+	c := newAnswerCache()
+	c.now = func() time.Time { return now }
+
+	wantErr := &net.DNSError{Err: "no such host", Name: "missing.example.", IsNotFound: true}
+	c.put("missing.example.", 1, nil, wantErr, 0)
+
+	now = now.Add(minNegativeTTL - time.Second)
+	if _, err, ok := c.get("missing.example.", 1); !ok || err != wantErr {
+		t.Fatalf("expected negative answer still cached just before minNegativeTTL, got ok=%v err=%v", ok, err)
+	}
+
+	now = now.Add(2 * time.Second)
+	if _, _, ok := c.get("missing.example.", 1); ok {
+		t.Fatalf("expected negative answer to expire after minNegativeTTL")
+	}
+}
+
+func TestAnswerCachePutSkipsUncacheablePositiveAnswer(t *testing.T) {
+	c := newAnswerCache()
+	c.put("example.com.", 1, []resourceRecord{{}}, nil, 0)
+
+	if _, _, ok := c.get("example.com.", 1); ok {
+		t.Fatalf("expected a positive answer with TTL 0 to not be cached, not forced into minNegativeTTL")
+	}
+}
+
+func TestAnswerCacheMissOnUnknownKey(t *testing.T) {
+	c := newAnswerCache()
+	c.put("example.com.", 1, []resourceRecord{{}}, nil, time.Minute)
+
+	if _, _, ok := c.get("example.com.", 28); ok {
+		t.Fatalf("expected miss for a qtype that was never cached")
+	}
+	if _, _, ok := c.get("other.example.", 1); ok {
+		t.Fatalf("expected miss for a name that was never cached")
+	}
+}
+
+func TestNilAnswerCacheDisablesCaching(t *testing.T) {
+	var c *answerCache
+	c.put("example.com.", 1, []resourceRecord{{}}, nil, time.Minute)
+	if _, _, ok := c.get("example.com.", 1); ok {
+		t.Fatalf("expected a nil *answerCache to never report a hit")
+	}
+}
@@ -0,0 +1,160 @@
+//go:build wasip1
+
+package wasip1
+
+import (
+	"net/netip"
+	"sort"
+)
+
+// policyEntry is one row of the RFC 6724 section 2.1 policy table: a destination (or source)
+// address matching prefix maps to a precedence (used for sorting) and a label (used to find
+// addresses that share a "matching label" with the source).
+type policyEntry struct {
+	prefix     netip.Prefix
+	precedence int
+	label      int
+}
+
+// defaultPolicyTable holds the default policy table given in RFC 6724 section 2.1.
+var defaultPolicyTable = []policyEntry{
+	{netip.MustParsePrefix("::1/128"), 50, 0},
+	{netip.MustParsePrefix("::/0"), 40, 1},
+	{netip.MustParsePrefix("::ffff:0:0/96"), 35, 4},
+	{netip.MustParsePrefix("2002::/16"), 30, 2},
+	{netip.MustParsePrefix("2001::/32"), 5, 5},
+	{netip.MustParsePrefix("fc00::/7"), 3, 13},
+	{netip.MustParsePrefix("::/96"), 1, 3},
+	{netip.MustParsePrefix("fec0::/10"), 1, 11},
+}
+
+// classify returns the precedence and label of addr according to table, using the longest
+// matching prefix as required by RFC 6724 section 2.1.
+func classify(table []policyEntry, addr netip.Addr) (precedence, label int) {
+	addr = addr.Unmap()
+	v6 := addr
+	if addr.Is4() {
+		v6 = netip.AddrFrom16(addr.As16())
+	}
+
+	best := -1
+	precedence, label = 40, 1 // the "::/0" default if nothing more specific matches
+	for _, entry := range table {
+		if !entry.prefix.Contains(v6) {
+			continue
+		}
+		if bits := entry.prefix.Bits(); bits > best {
+			best = bits
+			precedence, label = entry.precedence, entry.label
+		}
+	}
+	return precedence, label
+}
+
+// commonPrefixLen returns the number of leading bits shared between a and b.
+func commonPrefixLen(a, b netip.Addr) int {
+	ab, bb := a.As16(), b.As16()
+	n := 0
+	for i := range ab {
+		x := ab[i] ^ bb[i]
+		if x == 0 {
+			n += 8
+			continue
+		}
+		for x&0x80 == 0 {
+			n++
+			x <<= 1
+		}
+		break
+	}
+	return n
+}
+
+// assumedSourceLabel is used in place of the real source address's label for RFC 6724 Rule 5,
+// which WASI has no way to compute without a routing table. It matches the label of the "::/0"
+// row in defaultPolicyTable - the common case of a global unicast source - rather than being
+// derived from any one destination candidate.
+const assumedSourceLabel = 1
+
+// sortAddrs orders candidates by destination address selection, following the applicable rules
+// of RFC 6724 section 6 given only the destination addresses themselves. No local source address
+// or interface table is available under WASI, so Rules 1, 3, 4 and 8 (avoid unusable
+// destinations, avoid deprecated addresses, prefer home addresses, prefer smaller scope) are not
+// applied, Rules 2, 5 and 9 use the stand-ins described below, and Rule 7 treats IPv4-mapped
+// IPv6 addresses as the non-native ones.
+func sortAddrs(table []policyEntry, addrs []netip.Addr) {
+	type scored struct {
+		addr       netip.Addr
+		precedence int
+		label      int
+	}
+	scoredAddrs := make([]scored, len(addrs))
+	for i, a := range addrs {
+		precedence, label := classify(table, a)
+		scoredAddrs[i] = scored{a, precedence, label}
+	}
+
+	// anchor stands in for the source address Rule 9 is normally defined against; no source
+	// address is available under WASI, so the first candidate is used instead. It must be
+	// snapshotted once, up front: sort.SliceStable permutes scoredAddrs in place as it runs, so
+	// reading scoredAddrs[0] from inside the comparator would make the "anchor" change mid-sort
+	// and the comparator inconsistent.
+	anchor := addrs[0]
+
+	sort.SliceStable(scoredAddrs, func(i, j int) bool {
+		a, b := scoredAddrs[i], scoredAddrs[j]
+
+		// Rule 2: prefer matching scope. With no source address to compare against, rank each
+		// candidate with addrScope and put the wider scope first.
+		if as, bs := addrScope(a.addr), addrScope(b.addr); as != bs {
+			return as > bs
+		}
+
+		// Rule 5: prefer matching label. The real rule compares against the label of the source
+		// address that would be used to reach the destination, which WASI has no way to compute;
+		// assumedSourceLabel stands in for it. Using one of the candidates (e.g. addrs[0]) here
+		// instead would be self-referential - that candidate's label trivially matches itself,
+		// so it would always win this rule regardless of precedence.
+		if al, bl := a.label == assumedSourceLabel, b.label == assumedSourceLabel; al != bl {
+			return al
+		}
+
+		// Rule 6: prefer higher precedence.
+		if a.precedence != b.precedence {
+			return a.precedence > b.precedence
+		}
+
+		// Rule 7: prefer native transport (IPv4-mapped IPv6 addresses are tunneled).
+		if av, bv := a.addr.Is4In6(), b.addr.Is4In6(); av != bv {
+			return !av
+		}
+
+		// Rule 9: use longest matching prefix, comparing against anchor as a stand-in
+		// destination-affinity source since no source address is available.
+		if la, lb := commonPrefixLen(anchor, a.addr), commonPrefixLen(anchor, b.addr); la != lb {
+			return la > lb
+		}
+
+		// Rule 10: leave the relative order of ties as returned by the transport.
+		return false
+	})
+
+	for i, s := range scoredAddrs {
+		addrs[i] = s.addr
+	}
+}
+
+// addrScope ranks an address's scope for the Rule 2 step of sortAddrs: smaller values are
+// narrower in scope. Loopback/link-local addresses get the RFC's "link-local" value of 2,
+// private addresses (netip.Addr.IsPrivate) get the "site-local" value of 5, and all other
+// addresses get the "global" value of 14. No separate Rule 8 step uses it: any scope difference
+// is already decided by Rule 2, in favour of the wider scope.
+func addrScope(addr netip.Addr) int {
+	if addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() {
+		return 2
+	}
+	if addr.IsPrivate() {
+		return 5
+	}
+	return 14
+}
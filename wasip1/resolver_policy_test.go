@@ -0,0 +1,69 @@
+//go:build wasip1
+
+package wasip1
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestSortAddrsPrefersMatchingLabel(t *testing.T) {
+	// Isolate Rule 5 from Rule 6: two addresses with equal precedence but different labels,
+	// only one of which matches assumedSourceLabel. The default table gives each label its own
+	// precedence, so a custom table is used instead.
+	table := []policyEntry{
+		{netip.MustParsePrefix("2001:db8:1::/48"), 10, assumedSourceLabel},
+		{netip.MustParsePrefix("2001:db8:2::/48"), 10, 9},
+	}
+	matching := netip.MustParseAddr("2001:db8:1::1")   // precedence 10, label 1
+	mismatched := netip.MustParseAddr("2001:db8:2::1") // precedence 10, label 9
+
+	// mismatched comes first, so it is the Rule 9 anchor: only Rule 5 can move matching ahead.
+	addrs := []netip.Addr{mismatched, matching}
+	sortAddrs(table, addrs)
+
+	if addrs[0] != matching {
+		t.Fatalf("expected label-matching address first, got %v", addrs)
+	}
+	if addrs[1] != mismatched {
+		t.Fatalf("expected label-mismatched address last despite equal precedence, got %v", addrs)
+	}
+}
+
+func TestSortAddrsAnchorIsStableAcrossSort(t *testing.T) {
+	// Regression test: sortAddrs must snapshot the anchor once before sorting rather than
+	// reading the first element of the slice being sorted from inside the comparator, since
+	// sort.SliceStable permutes that slice in place as it runs.
+	a := netip.MustParseAddr("2001:db8::1")
+	b := netip.MustParseAddr("2001:db8::2")
+	c := netip.MustParseAddr("2001:db8:1::1")
+
+	addrs1 := []netip.Addr{a, b, c}
+	sortAddrs(defaultPolicyTable, addrs1)
+
+	addrs2 := []netip.Addr{c, b, a}
+	sortAddrs(defaultPolicyTable, addrs2)
+
+	if addrs1[0] != a || addrs2[0] != c {
+		t.Fatalf("expected the original first element of each input to remain the sort anchor: got %v and %v", addrs1, addrs2)
+	}
+}
+
+func TestSortAddrsPrefersHigherPrecedence(t *testing.T) {
+	// Use a custom table with two prefixes sharing a label, so Rule 5 ties and Rule 6
+	// (precedence) is what decides the order; the default table pairs each precedence with a
+	// distinct label, which would leave this case entangled with Rule 5 instead.
+	table := []policyEntry{
+		{netip.MustParsePrefix("2001:db8:1::/48"), 50, 9},
+		{netip.MustParsePrefix("2001:db8:2::/48"), 10, 9},
+	}
+	low := netip.MustParseAddr("2001:db8:2::1")  // precedence 10
+	high := netip.MustParseAddr("2001:db8:1::1") // precedence 50
+
+	addrs := []netip.Addr{low, high} // anchor is low, the lower-precedence address
+	sortAddrs(table, addrs)
+
+	if addrs[0] != high {
+		t.Fatalf("expected higher-precedence address first regardless of anchor, got %v", addrs)
+	}
+}
@@ -0,0 +1,70 @@
+//go:build wasip1
+
+package wasip1
+
+import (
+	"math/rand"
+	"net"
+	"sort"
+)
+
+// byPriorityWeight sorts SRV records by ascending priority and weight, mirroring the ordering
+// net/dnsclient.go applies to its own internal []*SRV before returning it from LookupSRV.
+type byPriorityWeight []*net.SRV
+
+func (s byPriorityWeight) Len() int { return len(s) }
+func (s byPriorityWeight) Less(i, j int) bool {
+	return s[i].Priority < s[j].Priority || (s[i].Priority == s[j].Priority && s[i].Weight < s[j].Weight)
+}
+func (s byPriorityWeight) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// shuffleByWeight shuffles SRV records by weight using the algorithm described in RFC 2782.
+func (s byPriorityWeight) shuffleByWeight() {
+	sum := 0
+	for _, addr := range s {
+		sum += int(addr.Weight)
+	}
+	for sum > 0 && len(s) > 1 {
+		n := rand.Intn(sum)
+		acc := 0
+		for i := range s {
+			acc += int(s[i].Weight)
+			if acc > n {
+				if i > 0 {
+					s[0], s[i] = s[i], s[0]
+				}
+				break
+			}
+		}
+		sum -= int(s[0].Weight)
+		s = s[1:]
+	}
+}
+
+// sort reorders SRV records as specified in RFC 2782.
+func (s byPriorityWeight) sort() {
+	sort.Sort(s)
+	i := 0
+	for j := 1; j < len(s); j++ {
+		if s[i].Priority != s[j].Priority {
+			s[i:j].shuffleByWeight()
+			i = j
+		}
+	}
+	s[i:].shuffleByWeight()
+}
+
+// byPref sorts MX records by preference, as specified in RFC 5321.
+type byPref []*net.MX
+
+func (s byPref) Len() int           { return len(s) }
+func (s byPref) Less(i, j int) bool { return s[i].Pref < s[j].Pref }
+func (s byPref) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func (s byPref) sort() {
+	for i := range s {
+		j := rand.Intn(i + 1)
+		s[i], s[j] = s[j], s[i]
+	}
+	sort.Sort(s)
+}
@@ -0,0 +1,119 @@
+//go:build wasip1
+
+package wasip1
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
+
+// dnsMessageMediaType is the media type RFC 8484 requires for DNS-over-HTTPS request and
+// response bodies.
+const dnsMessageMediaType = "application/dns-message"
+
+// dohTransport implements Transport as DNS-over-HTTPS (RFC 8484): query is POSTed as the request
+// body against URL and the response body is the raw DNS message.
+type dohTransport struct {
+	URL    string
+	Client *http.Client
+}
+
+func (t *dohTransport) httpClient() *http.Client {
+	if t.Client != nil {
+		return t.Client
+	}
+	return http.DefaultClient
+}
+
+func (t *dohTransport) Exchange(ctx context.Context, query []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(query))
+	if err != nil {
+		return nil, fmt.Errorf("wasip1: failed to build DoH request: %w", err)
+	}
+	req.Header.Set("Content-Type", dnsMessageMediaType)
+	req.Header.Set("Accept", dnsMessageMediaType)
+
+	resp, err := t.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("wasip1: DoH request to %s failed: %w", t.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("wasip1: DoH server %s returned status %d", t.URL, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// dotTransport implements Transport as DNS-over-TLS (RFC 7858), dialing Addr through this
+// package's own Dial so the connection is a WASI socket end to end, with no dependency on the
+// host's resolver or TLS stack beyond crypto/tls itself.
+type dotTransport struct {
+	// Addr is the "host:port" of the DoT server, e.g. "1.1.1.1:853".
+	Addr string
+	// ServerName sets the TLS server name when TLSConfig does not already set one; if both are
+	// empty it is derived from Addr.
+	ServerName string
+	// TLSConfig, if set, is cloned and used instead of building a default config.
+	TLSConfig *tls.Config
+}
+
+func (t *dotTransport) Exchange(ctx context.Context, query []byte) ([]byte, error) {
+	conn, err := Dial("tcp", t.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("wasip1: DoT dial to %s failed: %w", t.Addr, err)
+	}
+	defer conn.Close()
+
+	cfg := t.tlsConfig()
+	tlsConn := tls.Client(conn, cfg)
+	defer tlsConn.Close()
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, fmt.Errorf("wasip1: DoT handshake with %s failed: %w", t.Addr, err)
+	}
+
+	// RFC 7858 reuses the DNS-over-TCP message framing: a 2-byte big-endian length prefix
+	// ahead of the message itself.
+	var framed bytes.Buffer
+	if err := binary.Write(&framed, binary.BigEndian, uint16(len(query))); err != nil {
+		return nil, err
+	}
+	framed.Write(query)
+	if _, err := tlsConn.Write(framed.Bytes()); err != nil {
+		return nil, fmt.Errorf("wasip1: DoT write to %s failed: %w", t.Addr, err)
+	}
+
+	var length uint16
+	if err := binary.Read(tlsConn, binary.BigEndian, &length); err != nil {
+		return nil, fmt.Errorf("wasip1: DoT read length from %s failed: %w", t.Addr, err)
+	}
+	resp := make([]byte, length)
+	if _, err := io.ReadFull(tlsConn, resp); err != nil {
+		return nil, fmt.Errorf("wasip1: DoT read response from %s failed: %w", t.Addr, err)
+	}
+	return resp, nil
+}
+
+func (t *dotTransport) tlsConfig() *tls.Config {
+	var cfg *tls.Config
+	if t.TLSConfig != nil {
+		cfg = t.TLSConfig.Clone()
+	} else {
+		cfg = &tls.Config{}
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = t.ServerName
+	}
+	if cfg.ServerName == "" {
+		if host, _, err := net.SplitHostPort(t.Addr); err == nil {
+			cfg.ServerName = host
+		}
+	}
+	return cfg
+}
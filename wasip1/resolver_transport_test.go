@@ -0,0 +1,87 @@
+//go:build wasip1
+
+package wasip1
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestDoHTransportExchange(t *testing.T) {
+	query := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 42, RecursionDesired: true},
+		Questions: []dnsmessage.Question{
+			{Name: dnsmessage.MustNewName("example.com."), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET},
+		},
+	}
+	queryWire, err := query.Pack()
+	if err != nil {
+		t.Fatalf("failed to pack query: %v", err)
+	}
+
+	resp := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: 42, Response: true, RCode: dnsmessage.RCodeSuccess},
+		Questions: query.Questions,
+		Answers: []dnsmessage.Resource{
+			{
+				Header: dnsmessage.ResourceHeader{
+					Name:  dnsmessage.MustNewName("example.com."),
+					Type:  dnsmessage.TypeA,
+					Class: dnsmessage.ClassINET,
+					TTL:   300,
+				},
+				Body: &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}},
+			},
+		},
+	}
+	respWire, err := resp.Pack()
+	if err != nil {
+		t.Fatalf("failed to pack response: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != dnsMessageMediaType {
+			t.Errorf("expected Content-Type %q, got %q", dnsMessageMediaType, ct)
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %v", err)
+		}
+		if !bytes.Equal(body, queryWire) {
+			t.Errorf("request body didn't round-trip the query")
+		}
+		w.Header().Set("Content-Type", dnsMessageMediaType)
+		w.Write(respWire)
+	}))
+	defer srv.Close()
+
+	tr := &dohTransport{URL: srv.URL}
+	got, err := tr.Exchange(context.Background(), queryWire)
+	if err != nil {
+		t.Fatalf("Exchange returned error: %v", err)
+	}
+	if !bytes.Equal(got, respWire) {
+		t.Fatalf("Exchange returned %x, want %x", got, respWire)
+	}
+}
+
+func TestDoHTransportExchangeNonOKStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	tr := &dohTransport{URL: srv.URL}
+	if _, err := tr.Exchange(context.Background(), []byte("query")); err == nil {
+		t.Fatalf("expected an error for a non-200 DoH response")
+	}
+}
@@ -0,0 +1,87 @@
+//go:build wasip1
+
+package wasip1
+
+import (
+	"net"
+	"syscall"
+)
+
+// sockaddrToAddr converts a raw syscall.Sockaddr, as returned by
+// getsockname/getpeername/accept on a stream socket, into the *net.TCPAddr
+// used by this package's conn and listener. Use sockaddrToUDPAddr for the
+// datagram equivalent.
+func sockaddrToAddr(sa syscall.Sockaddr) net.Addr {
+	switch sa := sa.(type) {
+	case *syscall.SockaddrInet4:
+		return &net.TCPAddr{IP: append([]byte(nil), sa.Addr[:]...), Port: sa.Port}
+	case *syscall.SockaddrInet6:
+		return &net.TCPAddr{IP: append([]byte(nil), sa.Addr[:]...), Port: sa.Port, Zone: zoneForIndex(sa.ZoneId)}
+	default:
+		return nil
+	}
+}
+
+// sockaddrToUDPAddr converts a raw syscall.Sockaddr, as returned by
+// recvfrom on a datagram socket, into the *net.UDPAddr packetConn.ReadFrom
+// is expected to return.
+func sockaddrToUDPAddr(sa syscall.Sockaddr) net.Addr {
+	switch sa := sa.(type) {
+	case *syscall.SockaddrInet4:
+		return &net.UDPAddr{IP: append([]byte(nil), sa.Addr[:]...), Port: sa.Port}
+	case *syscall.SockaddrInet6:
+		return &net.UDPAddr{IP: append([]byte(nil), sa.Addr[:]...), Port: sa.Port, Zone: zoneForIndex(sa.ZoneId)}
+	default:
+		return nil
+	}
+}
+
+// addrToSockaddr converts a net.Addr into the raw syscall.Sockaddr required by
+// sendto/connect/bind.
+func addrToSockaddr(addr net.Addr) (syscall.Sockaddr, error) {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return ipToSockaddr(a.IP, a.Port, a.Zone)
+	case *net.UDPAddr:
+		return ipToSockaddr(a.IP, a.Port, a.Zone)
+	default:
+		return nil, &net.AddrError{Err: "unsupported address type for wasip1 socket", Addr: addr.String()}
+	}
+}
+
+// ipToSockaddr builds the raw syscall.Sockaddr for an IP/port/zone triple, choosing between
+// AF_INET and AF_INET6 representations depending on whether ip has a 4-byte form.
+func ipToSockaddr(ip net.IP, port int, zone string) (syscall.Sockaddr, error) {
+	if ip4 := ip.To4(); ip4 != nil {
+		sa := &syscall.SockaddrInet4{Port: port}
+		copy(sa.Addr[:], ip4)
+		return sa, nil
+	}
+	ip16 := ip.To16()
+	if ip16 == nil {
+		return nil, &net.AddrError{Err: "invalid IP address", Addr: ip.String()}
+	}
+	sa := &syscall.SockaddrInet6{Port: port, ZoneId: zoneIndex(zone)}
+	copy(sa.Addr[:], ip16)
+	return sa, nil
+}
+
+func zoneForIndex(index uint32) string {
+	if index == 0 {
+		return ""
+	}
+	if iface, err := net.InterfaceByIndex(int(index)); err == nil {
+		return iface.Name
+	}
+	return ""
+}
+
+func zoneIndex(zone string) uint32 {
+	if zone == "" {
+		return 0
+	}
+	if iface, err := net.InterfaceByName(zone); err == nil {
+		return uint32(iface.Index)
+	}
+	return 0
+}
@@ -0,0 +1,140 @@
+//go:build wasip1
+
+// Package wasip1 implements the net package replacements that the root
+// wasi-go-net package links against through //go:linkname. The functions
+// exported here back net.Dial, net.Dialer.Dial/DialContext, net.Listen,
+// net.ListenPacket, net.ListenConfig.Listen/ListenPacket and the
+// net.File{Conn,Listener,PacketConn} constructors with real WASI preview1
+// socket support, and the net.Resolver lookup methods with a DNS resolver
+// of its own.
+package wasip1
+
+import (
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// conn is the net.Conn implementation backing a WASI socket file
+// descriptor.
+type conn struct {
+	fd    int
+	file  *os.File
+	laddr net.Addr
+	raddr net.Addr
+}
+
+// newConn wraps fd in a net.Conn. laddr and raddr may be nil when the
+// caller has no address to report for them: WASI preview1 has no
+// sock_getsockname/sock_getpeername equivalent, so a conn built from an
+// already-open descriptor (e.g. via FileConn) cannot recover its
+// addresses after the fact.
+func newConn(fd int, laddr, raddr net.Addr) (*conn, error) {
+	return &conn{
+		fd:    fd,
+		file:  os.NewFile(uintptr(fd), "wasip1-socket"),
+		laddr: laddr,
+		raddr: raddr,
+	}, nil
+}
+
+func (c *conn) Read(b []byte) (int, error)  { return c.file.Read(b) }
+func (c *conn) Write(b []byte) (int, error) { return c.file.Write(b) }
+func (c *conn) Close() error                { return c.file.Close() }
+
+func (c *conn) LocalAddr() net.Addr  { return c.laddr }
+func (c *conn) RemoteAddr() net.Addr { return c.raddr }
+
+func (c *conn) SetDeadline(t time.Time) error      { return c.file.SetDeadline(t) }
+func (c *conn) SetReadDeadline(t time.Time) error  { return c.file.SetReadDeadline(t) }
+func (c *conn) SetWriteDeadline(t time.Time) error { return c.file.SetWriteDeadline(t) }
+
+// listener is the net.Listener implementation backing a WASI socket file
+// descriptor in listening mode.
+type listener struct {
+	fd    int
+	file  *os.File
+	laddr net.Addr
+}
+
+// newListener wraps fd in a net.Listener. laddr may be nil; see newConn.
+func newListener(fd int, laddr net.Addr) (*listener, error) {
+	return &listener{
+		fd:    fd,
+		file:  os.NewFile(uintptr(fd), "wasip1-socket"),
+		laddr: laddr,
+	}, nil
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	nfd, rsa, err := syscall.Accept(l.fd)
+	if err != nil {
+		return nil, os.NewSyscallError("accept", err)
+	}
+	if err := syscall.SetNonblock(nfd, true); err != nil {
+		syscall.Close(nfd)
+		return nil, os.NewSyscallError("setnonblock", err)
+	}
+	return newConn(nfd, l.laddr, sockaddrToAddr(rsa))
+}
+
+func (l *listener) Close() error   { return l.file.Close() }
+func (l *listener) Addr() net.Addr { return l.laddr }
+
+// packetConn is the net.PacketConn implementation backing a WASI datagram
+// socket file descriptor.
+type packetConn struct {
+	fd    int
+	file  *os.File
+	laddr net.Addr
+}
+
+// newPacketConn wraps fd in a net.PacketConn. laddr may be nil; see newConn.
+func newPacketConn(fd int, laddr net.Addr) (*packetConn, error) {
+	return &packetConn{
+		fd:    fd,
+		file:  os.NewFile(uintptr(fd), "wasip1-socket"),
+		laddr: laddr,
+	}, nil
+}
+
+func (p *packetConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, from, err := syscall.Recvfrom(p.fd, b, 0)
+	if err != nil {
+		return 0, nil, os.NewSyscallError("recvfrom", err)
+	}
+	return n, sockaddrToUDPAddr(from), nil
+}
+
+func (p *packetConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	sa, err := addrToSockaddr(addr)
+	if err != nil {
+		return 0, err
+	}
+	if err := syscall.Sendto(p.fd, b, 0, sa); err != nil {
+		return 0, os.NewSyscallError("sendto", err)
+	}
+	return len(b), nil
+}
+
+func (p *packetConn) Close() error                       { return p.file.Close() }
+func (p *packetConn) LocalAddr() net.Addr                { return p.laddr }
+func (p *packetConn) SetDeadline(t time.Time) error      { return p.file.SetDeadline(t) }
+func (p *packetConn) SetReadDeadline(t time.Time) error  { return p.file.SetReadDeadline(t) }
+func (p *packetConn) SetWriteDeadline(t time.Time) error { return p.file.SetWriteDeadline(t) }
+
+// dupNonblock duplicates the file descriptor behind f and puts the
+// duplicate into non-blocking mode, leaving f untouched so the caller
+// remains responsible for closing it.
+func dupNonblock(f *os.File) (int, error) {
+	fd, err := syscall.Dup(int(f.Fd()))
+	if err != nil {
+		return -1, os.NewSyscallError("dup", err)
+	}
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return -1, os.NewSyscallError("setnonblock", err)
+	}
+	return fd, nil
+}